@@ -0,0 +1,48 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package channel
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/url"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+)
+
+// ErrUnauthorized is returned (wrapped) by an AuthorizationChecker, and
+// surfaces from DispatchMessage/DispatchMessageWithRetries, when a
+// destination has at least one EventPolicy applied to it but the request
+// doesn't satisfy any of them. Callers should match it with errors.Is:
+// unlike a transport failure, an authorization rejection isn't transient,
+// so DispatchMessageWithRetries short-circuits dead-letter delivery for it
+// rather than forwarding the rejected event elsewhere.
+var ErrUnauthorized = errors.New("event rejected by authorization policy")
+
+// AuthorizationChecker is consulted by DispatchMessageWithRetries before
+// sending to destination. Implementations typically match the inbound
+// event's attributes and the caller's authenticated identity against the
+// eventing.knative.dev/v1alpha1 EventPolicy objects that apply to
+// destination; if none apply, Authorize should return nil so EventPolicy
+// remains opt-in per resource.
+type AuthorizationChecker interface {
+	// Authorize returns an error wrapping ErrUnauthorized if message is not
+	// permitted to be sent to destination. additionalHeaders is consulted
+	// for the caller's Authorization bearer token.
+	Authorize(ctx context.Context, destination *url.URL, message binding.Message, additionalHeaders nethttp.Header) error
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package channel
+
+import (
+	"context"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// protocolCloser is satisfied by the cloudevents protocol implementations
+// (kafka_sarama.Sender, cepubsub.Protocol, ...) that loadOrDial dials, all of
+// which close via a context rather than io.Closer's no-arg Close.
+type protocolCloser interface {
+	Close(ctx context.Context) error
+}
+
+// clientCache caches a cloudevents.Client per key (typically a target's
+// String()), so a Sender for a protocol binding with its own notion of a
+// long-lived connection (a Kafka producer, a Pub/Sub client, ...) doesn't
+// pay to dial a fresh one on every Send. Shared by kafkaSender and
+// pubsubSender; the next non-HTTP Sender (e.g. amqp://) should use this too
+// rather than copying the dial/race-loser-close logic again.
+type clientCache struct {
+	clients sync.Map // map[string]cloudevents.Client
+}
+
+// loadOrDial returns the cached cloudevents.Client for key, calling dial to
+// create and cache one if this is the first call to see it. dial also
+// returns the protocolCloser that owns the underlying connection, so the
+// client created by the loser of a concurrent dial race can be closed
+// rather than leaked.
+func (c *clientCache) loadOrDial(key string, dial func() (cloudevents.Client, protocolCloser, error)) (cloudevents.Client, error) {
+	if cached, ok := c.clients.Load(key); ok {
+		return cached.(cloudevents.Client), nil
+	}
+
+	client, closer, err := dial()
+	if err != nil {
+		return nil, err
+	}
+
+	if actual, loaded := c.clients.LoadOrStore(key, client); loaded {
+		// Lost the race to another concurrent call for the same key; close
+		// the protocol we just created and use the winner's instead.
+		_ = closer.Close(context.Background())
+		return actual.(cloudevents.Client), nil
+	}
+	return client, nil
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package channel
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/url"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+
+	eventingv1alpha1 "knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	eventingv1alpha1listers "knative.dev/eventing/pkg/client/listers/eventing/v1alpha1"
+)
+
+// fakeEventPolicyLister is a minimal eventingv1alpha1listers.EventPolicyLister
+// backed by a fixed slice, good enough to exercise Authorize without an
+// informer cache.
+type fakeEventPolicyLister struct {
+	policies []*eventingv1alpha1.EventPolicy
+}
+
+var _ eventingv1alpha1listers.EventPolicyLister = (*fakeEventPolicyLister)(nil)
+
+func (f *fakeEventPolicyLister) List(_ labels.Selector) ([]*eventingv1alpha1.EventPolicy, error) {
+	return f.policies, nil
+}
+
+func (f *fakeEventPolicyLister) EventPolicies(namespace string) eventingv1alpha1listers.EventPolicyNamespaceLister {
+	panic("not used by Authorize")
+}
+
+// fakeOIDCTokenVerifier is a stub OIDCTokenVerifier returning a fixed
+// subject/error pair regardless of the token presented.
+type fakeOIDCTokenVerifier struct {
+	subject string
+	err     error
+}
+
+func (f *fakeOIDCTokenVerifier) VerifyJWT(_ context.Context, _ string) (string, error) {
+	return f.subject, f.err
+}
+
+func newTestEvent() cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID("test-id")
+	event.SetType("example.event.type")
+	event.SetSource("example/source")
+	return event
+}
+
+func TestEventPolicyAuthorizationChecker_Authorize(t *testing.T) {
+	destination := &url.URL{Scheme: "http", Host: "example.svc.cluster.local", Path: "/"}
+	event := newTestEvent()
+
+	matchingPolicy := &eventingv1alpha1.EventPolicy{
+		Spec: eventingv1alpha1.EventPolicySpec{
+			From: []eventingv1alpha1.EventPolicySubject{{Sub: "allowed-subject"}},
+			Filters: []eventingv1alpha1.EventPolicyFilter{
+				{Type: event.Type(), Source: event.Source()},
+			},
+		},
+		Status: eventingv1alpha1.EventPolicyStatus{To: []string{destination.String()}},
+	}
+
+	tests := []struct {
+		name       string
+		policies   []*eventingv1alpha1.EventPolicy
+		verifier   *fakeOIDCTokenVerifier
+		headers    nethttp.Header
+		wantErr    bool
+		wantUnauth bool
+	}{
+		{
+			name:     "no applicable policy allows the request",
+			policies: nil,
+			verifier: &fakeOIDCTokenVerifier{subject: "allowed-subject"},
+			headers:  nethttp.Header{"Authorization": []string{"Bearer good-token"}},
+			wantErr:  false,
+		},
+		{
+			name:     "applicable policy with matching subject and filter allows the request",
+			policies: []*eventingv1alpha1.EventPolicy{matchingPolicy},
+			verifier: &fakeOIDCTokenVerifier{subject: "allowed-subject"},
+			headers:  nethttp.Header{"Authorization": []string{"Bearer good-token"}},
+			wantErr:  false,
+		},
+		{
+			name:       "applicable policy rejects the wrong subject",
+			policies:   []*eventingv1alpha1.EventPolicy{matchingPolicy},
+			verifier:   &fakeOIDCTokenVerifier{subject: "some-other-subject"},
+			headers:    nethttp.Header{"Authorization": []string{"Bearer good-token"}},
+			wantErr:    true,
+			wantUnauth: true,
+		},
+		{
+			name:       "missing bearer token is unauthorized",
+			policies:   []*eventingv1alpha1.EventPolicy{matchingPolicy},
+			verifier:   &fakeOIDCTokenVerifier{subject: "allowed-subject"},
+			headers:    nethttp.Header{},
+			wantErr:    true,
+			wantUnauth: true,
+		},
+		{
+			name:       "garbled bearer token is unauthorized",
+			policies:   []*eventingv1alpha1.EventPolicy{matchingPolicy},
+			verifier:   &fakeOIDCTokenVerifier{subject: "allowed-subject"},
+			headers:    nethttp.Header{"Authorization": []string{"not-a-bearer-token"}},
+			wantErr:    true,
+			wantUnauth: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checker := NewEventPolicyAuthorizationChecker(zap.NewNop(), &fakeEventPolicyLister{policies: test.policies}, test.verifier)
+
+			message := binding.EventMessage(event)
+			err := checker.Authorize(context.Background(), destination, message, test.headers)
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Authorize() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantUnauth && !errors.Is(err, ErrUnauthorized) {
+				t.Errorf("Authorize() error = %v, want it to wrap ErrUnauthorized", err)
+			}
+		})
+	}
+}
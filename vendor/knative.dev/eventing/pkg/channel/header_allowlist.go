@@ -0,0 +1,78 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package channel
+
+import (
+	nethttp "net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// HeaderProxyAllowList controls which headers observed on one hop (a
+// destination's response, or the original request) are forwarded on to the
+// next (a reply or dead letter sink). This exists because blindly proxying
+// headers received from an arbitrary subscriber, as utils.PassThroughHeaders
+// alone does, leaks things like Authorization, Cookie and Set-Cookie to a
+// destination that never should have seen them.
+type HeaderProxyAllowList struct {
+	allowed sets.String
+}
+
+// NewHeaderProxyAllowList builds a HeaderProxyAllowList permitting the given
+// headers in addition to all CloudEvents ce-* attribute headers, which are
+// always allowed through regardless of this list.
+func NewHeaderProxyAllowList(headers ...string) HeaderProxyAllowList {
+	allowed := sets.NewString()
+	for _, h := range headers {
+		allowed.Insert(nethttp.CanonicalHeaderKey(h))
+	}
+	return HeaderProxyAllowList{allowed: allowed}
+}
+
+// DefaultHeaderProxyAllowList is used when a dispatcher isn't configured
+// with its own HeaderProxyAllowList. It covers the transport metadata a
+// reply or dead letter sink legitimately needs, and nothing a subscriber
+// could use to impersonate or redirect the caller.
+var DefaultHeaderProxyAllowList = NewHeaderProxyAllowList(
+	"Content-Type",
+	"Content-Length",
+	"Traceparent",
+	"Tracestate",
+	"Prefer",
+	"Retry-After",
+)
+
+// cloudEventsHeaderPrefix is the prefix used by binary-mode CloudEvents
+// HTTP headers (e.g. ce-id, ce-source, ce-type); these are always forwarded
+// regardless of the allow-list since they're the event itself, not
+// destination-specific transport state.
+const cloudEventsHeaderPrefix = "ce-"
+
+// Filter returns the subset of headers permitted by l: every ce-* header,
+// plus whatever was passed to NewHeaderProxyAllowList. Everything else
+// (notably Authorization, Cookie, Set-Cookie, and arbitrary X-* headers) is
+// dropped.
+func (l HeaderProxyAllowList) Filter(headers nethttp.Header) nethttp.Header {
+	out := make(nethttp.Header, len(headers))
+	for key, values := range headers {
+		if strings.HasPrefix(strings.ToLower(key), cloudEventsHeaderPrefix) || l.allowed.Has(nethttp.CanonicalHeaderKey(key)) {
+			out[key] = values
+		}
+	}
+	return out
+}
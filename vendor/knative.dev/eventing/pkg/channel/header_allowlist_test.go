@@ -0,0 +1,83 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package channel
+
+import (
+	nethttp "net/http"
+	"testing"
+)
+
+func TestDefaultHeaderProxyAllowListFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "sensitive: authorization is stripped", header: "Authorization", want: false},
+		{name: "sensitive: cookie is stripped", header: "Cookie", want: false},
+		{name: "sensitive: set-cookie is stripped", header: "Set-Cookie", want: false},
+		{name: "arbitrary X- header is stripped", header: "X-Forwarded-For", want: false},
+		{name: "arbitrary header is stripped", header: "Some-Custom-Header", want: false},
+		{name: "ce-* cloudevents header is always allowed", header: "Ce-Id", want: true},
+		{name: "ce-* cloudevents header is case-insensitively allowed", header: "CE-Source", want: true},
+		{name: "content-type is allowed", header: "Content-Type", want: true},
+		{name: "content-length is allowed", header: "Content-Length", want: true},
+		{name: "traceparent is allowed", header: "Traceparent", want: true},
+		{name: "tracestate is allowed", header: "Tracestate", want: true},
+		{name: "prefer is allowed", header: "Prefer", want: true},
+		{name: "retry-after is allowed", header: "Retry-After", want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			in := nethttp.Header{}
+			in.Set(test.header, "some-value")
+
+			out := DefaultHeaderProxyAllowList.Filter(in)
+
+			_, got := out[nethttp.CanonicalHeaderKey(test.header)]
+			if got != test.want {
+				t.Errorf("Filter(%q) present = %v, want %v", test.header, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHeaderProxyAllowListFilterCustom(t *testing.T) {
+	allowList := NewHeaderProxyAllowList("X-Custom-Allowed")
+
+	in := nethttp.Header{}
+	in.Set("X-Custom-Allowed", "yes")
+	in.Set("X-Custom-Denied", "no")
+	in.Set("Authorization", "Bearer token")
+	in.Set("Ce-Type", "example.event")
+
+	out := allowList.Filter(in)
+
+	if _, ok := out["X-Custom-Allowed"]; !ok {
+		t.Error("expected X-Custom-Allowed to be present, it was stripped")
+	}
+	if _, ok := out["X-Custom-Denied"]; ok {
+		t.Error("expected X-Custom-Denied to be stripped, it was present")
+	}
+	if _, ok := out["Authorization"]; ok {
+		t.Error("expected Authorization to be stripped, it was present")
+	}
+	if _, ok := out["Ce-Type"]; !ok {
+		t.Error("expected Ce-Type to be present, it was stripped")
+	}
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package channel
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/url"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol/http"
+
+	"knative.dev/eventing/pkg/kncloudevents"
+)
+
+// httpResponseError is returned by httpSender when a destination responds
+// with a non-2xx status, carrying enough of the response for the dispatcher
+// to decide whether to retry and, on exhaustion, to annotate the event
+// forwarded to the dead letter sink.
+type httpResponseError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *httpResponseError) Error() string {
+	return fmt.Sprintf("unexpected HTTP response, expected 2xx, got %d", e.StatusCode)
+}
+
+// httpSender is the Sender registered for http:// and https:// destinations.
+// It is the only Sender that implements caCertsSender, since TLS trust is a
+// property of HTTP(S), not of the other protocol bindings.
+type httpSender struct {
+	sender *kncloudevents.HttpMessageSender
+
+	// caCertsProvider supplies a process-wide CA trust bundle (e.g. the
+	// contents of a mounted mTLS secret) to trust in addition to the system
+	// pool and any per-destination CACerts passed to SendWithCACerts.
+	caCertsProvider CACertsProvider
+
+	// tlsPools caches the *x509.CertPool built for a given PEM bundle so we
+	// don't reparse it on every dispatch.
+	tlsPools sync.Map // map[string]*x509.CertPool
+
+	// headerAllowList filters which response headers are forwarded on to a
+	// reply or dead letter sink; see SendWithCACerts.
+	headerAllowList HeaderProxyAllowList
+}
+
+var _ caCertsSender = (*httpSender)(nil)
+
+// newHTTPSender creates the HTTP(S) Sender based on config. If tlsConfig is
+// non-nil it configures the underlying kncloudevents.HttpMessageSender's
+// transport (e.g. for client certificates); this is distinct from, and
+// applies regardless of, the per-destination CACerts handled by
+// SendWithCACerts.
+func newHTTPSender(config EventDispatcherConfig, tlsConfig *tls.Config, caCertsProvider CACertsProvider, headerAllowList HeaderProxyAllowList) (*httpSender, error) {
+	sender, err := kncloudevents.NewHttpMessageSender(&config.ConnectionArgs, "")
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		sender.Client.Transport = &nethttp.Transport{TLSClientConfig: tlsConfig}
+	}
+	return &httpSender{sender: sender, caCertsProvider: caCertsProvider, headerAllowList: headerAllowList}, nil
+}
+
+func (s *httpSender) Send(ctx context.Context, message binding.Message, target *url.URL, additionalHeaders nethttp.Header) (binding.Message, nethttp.Header, error) {
+	return s.SendWithCACerts(ctx, message, target, additionalHeaders, nil)
+}
+
+func (s *httpSender) SendWithCACerts(ctx context.Context, message binding.Message, target *url.URL, additionalHeaders nethttp.Header, caCerts *string) (binding.Message, nethttp.Header, error) {
+	req, err := s.sender.NewCloudEventRequestWithTarget(ctx, target.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := kncloudevents.WriteHttpRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders); err != nil {
+		return nil, nil, err
+	}
+
+	client, err := s.clientFor(target, caCerts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to build TLS transport for %s: %w", target, err)
+	}
+
+	var response *nethttp.Response
+	if client != nil {
+		response, err = client.Do(req)
+	} else {
+		response, err = s.sender.Send(req)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if isFailure(response.StatusCode) {
+		// Reject non-successful responses, but keep the body around so the
+		// caller can retry and/or report it to a dead letter sink.
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		return nil, nil, &httpResponseError{StatusCode: response.StatusCode, Body: body}
+	}
+	responseMessage := http.NewMessageFromHttpResponse(response)
+	return responseMessage, s.headerAllowList.Filter(response.Header), nil
+}
+
+// clientFor returns nil (use the dispatcher's default client) unless target
+// is dialed over https:// and either a per-destination caCerts PEM bundle or
+// a process-wide caCertsProvider was supplied, in which case it returns a
+// one-off client trusting that bundle in addition to the system pool, and
+// keeping the tlsConfig (e.g. client certificates) given to newHTTPSender.
+func (s *httpSender) clientFor(target *url.URL, caCerts *string) (*nethttp.Client, error) {
+	if target.Scheme != "https" || (caCerts == nil && s.caCertsProvider == nil) {
+		return nil, nil
+	}
+
+	pool, err := s.certPoolFor(caCerts)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{}
+	if transport, ok := s.sender.Client.Transport.(*nethttp.Transport); ok && transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	tlsConfig.RootCAs = pool
+	return &nethttp.Client{
+		Transport: &nethttp.Transport{TLSClientConfig: tlsConfig},
+	}, nil
+}
+
+// certPoolFor returns the system cert pool extended with caCerts and whatever
+// s.caCertsProvider currently returns, caching the result by PEM content so a
+// rotated secret produces a fresh pool on its next read.
+func (s *httpSender) certPoolFor(caCerts *string) (*x509.CertPool, error) {
+	pem := ""
+	if caCerts != nil {
+		pem += *caCerts
+	}
+	if s.caCertsProvider != nil {
+		providerCerts, err := s.caCertsProvider()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA certs: %w", err)
+		}
+		if providerCerts != nil {
+			pem += *providerCerts
+		}
+	}
+	if pem == "" {
+		return x509.SystemCertPool()
+	}
+	if cached, ok := s.tlsPools.Load(pem); ok {
+		return cached.(*x509.CertPool), nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM([]byte(pem)) {
+		return nil, fmt.Errorf("no certificates found in CACerts")
+	}
+	s.tlsPools.Store(pem, pool)
+	return pool, nil
+}
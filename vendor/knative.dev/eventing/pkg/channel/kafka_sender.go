@@ -0,0 +1,102 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package channel
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	kafka_sarama "github.com/cloudevents/sdk-go/v2/protocol/kafka_sarama"
+	"go.uber.org/zap"
+)
+
+// kafkaSender is the Sender registered for kafka:// destinations, of the
+// form kafka://broker1:9092,broker2:9092/topic. Kafka has no notion of a
+// synchronous HTTP-style response, so Send never returns a response message
+// to forward to reply; a Trigger or Subscription wanting a reply from a
+// Kafka-backed subscriber needs its own reply topic wired up independently.
+type kafkaSender struct {
+	logger *zap.Logger
+
+	// clients caches the cloudevents.Client (and its underlying sarama
+	// producer) for each target already dialed, keyed by target.String(),
+	// so Send doesn't pay a fresh broker connection and metadata fetch on
+	// every event.
+	clients clientCache
+}
+
+var _ Sender = (*kafkaSender)(nil)
+
+func newKafkaSender(logger *zap.Logger) *kafkaSender {
+	return &kafkaSender{logger: logger}
+}
+
+func (s *kafkaSender) Send(ctx context.Context, message binding.Message, target *url.URL, additionalHeaders nethttp.Header) (binding.Message, nethttp.Header, error) {
+	client, err := s.clientFor(target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	event, err := binding.ToEvent(ctx, message)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read event to send to %s: %w", target, err)
+	}
+
+	if result := client.Send(ctx, *event); cloudevents.IsUndelivered(result) {
+		return nil, nil, fmt.Errorf("unable to send event to %s: %w", target, result)
+	}
+
+	s.logger.Debug("Dispatched event to kafka", zap.String("url", target.String()))
+	return nil, nil, nil
+}
+
+// clientFor returns the cached cloudevents.Client for target, dialing and
+// caching one if this is the first Send to see it.
+func (s *kafkaSender) clientFor(target *url.URL) (cloudevents.Client, error) {
+	brokers, topic, err := parseKafkaTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.clients.loadOrDial(target.String(), func() (cloudevents.Client, protocolCloser, error) {
+		protocol, err := kafka_sarama.NewSender(brokers, sarama.NewConfig(), topic)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to create kafka sender for %s: %w", target, err)
+		}
+		client, err := cloudevents.NewClient(protocol)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to create kafka client for %s: %w", target, err)
+		}
+		return client, protocol, nil
+	})
+}
+
+// parseKafkaTarget splits a kafka://broker1,broker2/topic destination into
+// its broker list and topic.
+func parseKafkaTarget(target *url.URL) ([]string, string, error) {
+	topic := strings.TrimPrefix(target.Path, "/")
+	if target.Host == "" || topic == "" {
+		return nil, "", fmt.Errorf("kafka destination %s must be of the form kafka://broker1,broker2/topic", target)
+	}
+	return strings.Split(target.Host, ","), topic, nil
+}
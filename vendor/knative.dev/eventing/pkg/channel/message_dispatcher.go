@@ -18,35 +18,126 @@ package channel
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	nethttp "net/http"
 	"net/url"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/binding"
-	"github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/cloudevents/sdk-go/v2/binding/buffering"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
-	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// CACertsProvider returns the PEM encoded CA trust bundle to use when dialing
+// destinations over TLS. It is consulted on every dial so callers can re-read
+// a mounted secret after rotation instead of baking a pool in at construction
+// time.
+type CACertsProvider func() (*string, error)
 
-	"knative.dev/eventing/pkg/kncloudevents"
-	"knative.dev/eventing/pkg/utils"
+// Sender abstracts the protocol binding used to deliver a CloudEvent to a
+// single destination. Implementations are selected by the destination URL's
+// scheme (see MessageDispatcherImpl.senders), which lets DispatchMessage fan
+// out to non-HTTP subscribers (e.g. kafka://, pubsub://) and receive their
+// replies as CloudEvents through the same dispatch/dead-letter/
+// response-forward state machine used for HTTP.
+type Sender interface {
+	// Send delivers message to target. A non-nil returned message is a
+	// response CloudEvent to forward to reply; protocols without a
+	// synchronous response (Kafka, Pub/Sub) always return a nil message.
+	// message may be sent more than once if the caller is retrying, so
+	// Send must not assume it can only be read once.
+	Send(ctx context.Context, message binding.Message, target *url.URL, additionalHeaders nethttp.Header) (binding.Message, nethttp.Header, error)
+}
+
+// caCertsSender is implemented by Senders whose protocol has a notion of
+// server TLS trust (currently just HTTP/HTTPS), letting executeRequestWithRetries pass
+// along a per-destination CA bundle. Senders for protocols without this
+// notion (Kafka, Pub/Sub) don't implement it and are called via Send.
+type caCertsSender interface {
+	Sender
+	SendWithCACerts(ctx context.Context, message binding.Message, target *url.URL, additionalHeaders nethttp.Header, caCerts *string) (binding.Message, nethttp.Header, error)
+}
+
+// BackoffPolicy is the shape of the delay between retry attempts in a
+// RetryConfig.
+type BackoffPolicy string
+
+const (
+	BackoffPolicyLinear      BackoffPolicy = "linear"
+	BackoffPolicyExponential BackoffPolicy = "exponential"
+)
+
+// RetryConfig controls DispatchMessageWithRetries' retry behavior against a
+// single destination; destination and reply are each retried independently
+// using the same RetryConfig. The zero value disables retries.
+type RetryConfig struct {
+	// RetryMax is the number of retry attempts after an initial failed send.
+	RetryMax int
+	// BackoffPolicy selects how BackoffDelay grows between attempts.
+	BackoffPolicy BackoffPolicy
+	// BackoffDelay is the base delay between attempts.
+	BackoffDelay time.Duration
+	// RetryableCodes overrides the default set of retryable non-5xx HTTP
+	// status codes (408 and 429); any 5xx is retried regardless. Ignored by
+	// non-HTTP Senders.
+	RetryableCodes []int
+}
+
+// DispatchExecutionInfo carries the last response observed for a destination
+// that failed all of its attempts, so DispatchMessageWithRetries can
+// annotate the event forwarded to the dead letter sink with it.
+type DispatchExecutionInfo struct {
+	ResponseCode int
+	ResponseBody []byte
+}
+
+// CloudEvent extension attributes set on events forwarded to a dead letter
+// sink, carrying the last response observed from the destination or reply
+// that rejected the event.
+const (
+	deadLetterErrorDestinationExtension = "knativeerrordest"
+	deadLetterErrorCodeExtension        = "knativeerrorcode"
+	deadLetterErrorDataExtension        = "knativeerrordata"
 )
 
 type MessageDispatcher interface {
-	// DispatchMessage dispatches an event to a destination over HTTP.
+	// DispatchMessage dispatches an event to a destination, using the Sender
+	// registered for the destination URL's scheme.
 	//
-	// The destination and reply are URLs.
+	// The destination and reply are URLs. No per-destination CA certs are
+	// trusted and nothing is retried; see DispatchMessageWithRetries.
 	DispatchMessage(ctx context.Context, message cloudevents.Message, additionalHeaders nethttp.Header, destination *url.URL, reply *url.URL, deadLetter *url.URL) error
+
+	// DispatchMessageWithRetries is like DispatchMessage, but additionally
+	// trusts the given per-destination CA certs when dialing over https://
+	// (optional PEM encoded CA trust bundles, the same shape addressables
+	// expose in their status, e.g. IMC's addressStatus),
+	// and retries destination and reply independently according to
+	// retryConfig (nil disables retries) before falling back to deadLetter.
+	DispatchMessageWithRetries(ctx context.Context, message cloudevents.Message, additionalHeaders nethttp.Header, destination *url.URL, destinationCACerts *string, reply *url.URL, replyCACerts *string, deadLetter *url.URL, deadLetterCACerts *string, retryConfig *RetryConfig) error
 }
 
 // MessageDispatcherImpl is the 'real' MessageDispatcher used everywhere except unit tests.
 var _ MessageDispatcher = &MessageDispatcherImpl{}
 
-// MessageDispatcherImpl dispatches events to a destination over HTTP.
+// MessageDispatcherImpl dispatches events to a destination, selecting the
+// protocol binding to use from senders based on the destination's URL scheme.
 type MessageDispatcherImpl struct {
-	sender           *kncloudevents.HttpMessageSender
-	supportedSchemes sets.String
+	senders map[string]Sender
+
+	// authChecker, when non-nil, is consulted before every destination send;
+	// see DispatchMessageWithRetries.
+	authChecker AuthorizationChecker
+
+	// headerAllowList filters the headers forwarded on to a dead letter sink
+	// (the original request's, or those forwarded to reply); see
+	// DispatchMessageWithRetries. Response headers forwarded to reply are
+	// filtered by the HTTP Sender's own allow-list.
+	headerAllowList HeaderProxyAllowList
 
 	logger *zap.Logger
 }
@@ -59,19 +150,68 @@ func NewMessageDispatcher(logger *zap.Logger) *MessageDispatcherImpl {
 
 // NewMessageDispatcherFromConfig creates a new event dispatcher based on config.
 func NewMessageDispatcherFromConfig(logger *zap.Logger, config EventDispatcherConfig) *MessageDispatcherImpl {
-	sender, err := kncloudevents.NewHttpMessageSender(&config.ConnectionArgs, "")
+	return NewMessageDispatcherFromConfigWithTLS(logger, config, nil, nil)
+}
+
+// NewMessageDispatcherFromConfigWithTLS creates a new event dispatcher based on
+// config, additionally configuring the HTTP/HTTPS sender's transport with
+// tlsConfig so destinations that require client certificates (e.g. another
+// in-mesh component when Istio mTLS is not available) can be dispatched to.
+// caCertsProvider, when non-nil, is consulted on every HTTPS dial so a
+// rotated CA bundle mounted from a secret is picked up without restarting.
+//
+// In addition to http:// and https://, the returned dispatcher supports
+// kafka:// and pubsub:// destinations out of the box; use RegisterSender to
+// add further protocol bindings (e.g. amqp://), and SetAuthorizationChecker
+// to enforce EventPolicy before dispatching to a destination.
+func NewMessageDispatcherFromConfigWithTLS(logger *zap.Logger, config EventDispatcherConfig, tlsConfig *tls.Config, caCertsProvider CACertsProvider) *MessageDispatcherImpl {
+	return NewMessageDispatcherFromConfigWithTLSAndHeaderAllowList(logger, config, tlsConfig, caCertsProvider, DefaultHeaderProxyAllowList)
+}
+
+// NewMessageDispatcherFromConfigWithTLSAndHeaderAllowList is like
+// NewMessageDispatcherFromConfigWithTLS, but additionally lets the caller
+// replace DefaultHeaderProxyAllowList, e.g. to permit a deployment-specific
+// header that DefaultHeaderProxyAllowList doesn't know about.
+func NewMessageDispatcherFromConfigWithTLSAndHeaderAllowList(logger *zap.Logger, config EventDispatcherConfig, tlsConfig *tls.Config, caCertsProvider CACertsProvider, headerAllowList HeaderProxyAllowList) *MessageDispatcherImpl {
+	http, err := newHTTPSender(config, tlsConfig, caCertsProvider, headerAllowList)
 	if err != nil {
 		logger.Fatal("Unable to create cloudevents binding sender", zap.Error(err))
 		return nil
 	}
-	return &MessageDispatcherImpl{
-		sender:           sender,
-		supportedSchemes: sets.NewString("http", "https"),
-		logger:           logger,
+
+	d := &MessageDispatcherImpl{
+		senders: map[string]Sender{
+			"http":   http,
+			"https":  http,
+			"kafka":  newKafkaSender(logger),
+			"pubsub": newPubSubSender(logger),
+			// TODO: register an amqp:// sender once a Go AMQP 1.0 client
+			// dependency is pulled in (see cloudevents/sdk-go/v2/protocol/amqp).
+		},
+		headerAllowList: headerAllowList,
+		logger:          logger,
 	}
+	return d
+}
+
+// SetAuthorizationChecker configures the AuthorizationChecker consulted
+// before every destination send. Passing nil disables authorization
+// enforcement (the default).
+func (d *MessageDispatcherImpl) SetAuthorizationChecker(authChecker AuthorizationChecker) {
+	d.authChecker = authChecker
+}
+
+// RegisterSender adds or replaces the Sender used to dispatch to destination
+// URLs with the given scheme.
+func (d *MessageDispatcherImpl) RegisterSender(scheme string, sender Sender) {
+	d.senders[scheme] = sender
 }
 
 func (d *MessageDispatcherImpl) DispatchMessage(ctx context.Context, initialMessage cloudevents.Message, initialAdditionalHeaders nethttp.Header, destination *url.URL, reply *url.URL, deadLetter *url.URL) error {
+	return d.DispatchMessageWithRetries(ctx, initialMessage, initialAdditionalHeaders, destination, nil, reply, nil, deadLetter, nil, nil)
+}
+
+func (d *MessageDispatcherImpl) DispatchMessageWithRetries(ctx context.Context, initialMessage cloudevents.Message, initialAdditionalHeaders nethttp.Header, destination *url.URL, destinationCACerts *string, reply *url.URL, replyCACerts *string, deadLetter *url.URL, deadLetterCACerts *string, retryConfig *RetryConfig) error {
 	// All messages that should be finished at the end of this function
 	// are placed in this slice
 	var messagesToFinish []binding.Message
@@ -92,15 +232,44 @@ func (d *MessageDispatcherImpl) DispatchMessage(ctx context.Context, initialMess
 	var responseAdditionalHeaders nethttp.Header
 
 	if destination != nil {
-		var err error
-		// Try to send to destination
-		messagesToFinish = append(messagesToFinish, initialMessage)
+		// initialMessage may need to be read by Authorize below, sent to
+		// destination, retried, and (on exhaustion) sent again to
+		// deadLetter, so buffer it once up front rather than relying on it
+		// being re-readable.
+		bufferedMessage, bufferedFinish, err := buffering.CopyMessage(ctx, initialMessage)
+		if err != nil {
+			return fmt.Errorf("unable to buffer event for %s: %w", destination, err)
+		}
+		// initialMessage is fully consumed by CopyMessage above and never
+		// read again, so it must be finished here too; only bufferedMessage
+		// being finished left the caller's original message (e.g. the
+		// inbound request body/ack) unfinished on this path.
+		messagesToFinish = append(messagesToFinish, initialMessage, bufferedMessage)
+		defer func() { _ = bufferedFinish() }()
+
+		// Authorization failures are not transient: short-circuit
+		// dead-letter delivery and fail outright rather than retrying or
+		// forwarding the rejected event elsewhere. Checked against
+		// bufferedMessage, not initialMessage: EventPolicyAuthorizationChecker
+		// reads the event to match it against policy filters, and
+		// initialMessage is a one-shot binding.Message that a read would
+		// otherwise drain before it's ever sent to destination.
+		if d.authChecker != nil {
+			if err := d.authChecker.Authorize(ctx, destination, bufferedMessage, initialAdditionalHeaders); err != nil {
+				return fmt.Errorf("unable to send to %s: %w", destination, err)
+			}
+		}
 
-		responseMessage, responseAdditionalHeaders, err = d.executeRequest(ctx, destination, initialMessage, initialAdditionalHeaders)
+		var info *DispatchExecutionInfo
+		responseMessage, responseAdditionalHeaders, info, err = d.executeRequestWithRetries(ctx, destination, destinationCACerts, bufferedMessage, initialAdditionalHeaders, retryConfig)
 		if err != nil {
 			// DeadLetter is configured, send the message to it
 			if deadLetter != nil {
-				deadLetterResponse, _, deadLetterErr := d.executeRequest(ctx, deadLetter, initialMessage, initialAdditionalHeaders)
+				deadLetterMessage, deadLetterErr := withDeadLetterExtensions(ctx, bufferedMessage, destination, info)
+				if deadLetterErr != nil {
+					return fmt.Errorf("unable to annotate event for dead letter sink %s: %w", deadLetter, deadLetterErr)
+				}
+				deadLetterResponse, _, _, deadLetterErr := d.executeRequestWithRetries(ctx, deadLetter, deadLetterCACerts, deadLetterMessage, d.headerAllowList.Filter(initialAdditionalHeaders), retryConfig)
 				if deadLetterErr != nil {
 					return fmt.Errorf("unable to complete request to either %s (%v) or %s (%v)", destination, err, deadLetter, deadLetterErr)
 				}
@@ -131,11 +300,23 @@ func (d *MessageDispatcherImpl) DispatchMessage(ctx context.Context, initialMess
 		return nil
 	}
 
-	responseResponseMessage, _, err := d.executeRequest(ctx, reply, responseMessage, responseAdditionalHeaders)
+	bufferedResponse, bufferedResponseFinish, err := buffering.CopyMessage(ctx, responseMessage)
+	if err != nil {
+		return fmt.Errorf("unable to buffer response for %s: %w", reply, err)
+	}
+	messagesToFinish = append(messagesToFinish, bufferedResponse)
+	defer func() { _ = bufferedResponseFinish() }()
+
+	var replyInfo *DispatchExecutionInfo
+	responseResponseMessage, _, replyInfo, err := d.executeRequestWithRetries(ctx, reply, replyCACerts, bufferedResponse, responseAdditionalHeaders, retryConfig)
 	if err != nil {
 		// DeadLetter is configured, send the message to it
 		if deadLetter != nil {
-			deadLetterResponse, _, deadLetterErr := d.executeRequest(ctx, deadLetter, initialMessage, responseAdditionalHeaders)
+			deadLetterMessage, deadLetterErr := withDeadLetterExtensions(ctx, bufferedResponse, reply, replyInfo)
+			if deadLetterErr != nil {
+				return fmt.Errorf("unable to annotate event for dead letter sink %s: %w", deadLetter, deadLetterErr)
+			}
+			deadLetterResponse, _, _, deadLetterErr := d.executeRequestWithRetries(ctx, deadLetter, deadLetterCACerts, deadLetterMessage, d.headerAllowList.Filter(responseAdditionalHeaders), retryConfig)
 			if deadLetterErr != nil {
 				return fmt.Errorf("failed to forward reply to %s (%v) and failed to send it to the dead letter sink %s (%v)", reply, err, deadLetter, deadLetterErr)
 			}
@@ -155,43 +336,120 @@ func (d *MessageDispatcherImpl) DispatchMessage(ctx context.Context, initialMess
 	return nil
 }
 
-func (d *MessageDispatcherImpl) executeRequest(ctx context.Context, url *url.URL, message cloudevents.Message, additionalHeaders nethttp.Header) (cloudevents.Message, nethttp.Header, error) {
-	d.logger.Debug("Dispatching event", zap.String("url", url.String()))
+// executeRequestWithRetries dispatches message to target using the Sender
+// registered for target's scheme, retrying transient failures according to
+// retryConfig (nil disables retries). It returns the response to forward
+// (if any), and, when every attempt failed, a DispatchExecutionInfo
+// describing the last one for dead-letter annotation.
+func (d *MessageDispatcherImpl) executeRequestWithRetries(ctx context.Context, target *url.URL, caCerts *string, message binding.Message, additionalHeaders nethttp.Header, retryConfig *RetryConfig) (cloudevents.Message, nethttp.Header, *DispatchExecutionInfo, error) {
+	d.logger.Debug("Dispatching event", zap.String("url", target.String()))
 
-	ctx, span := trace.StartSpan(ctx, "knative.dev", trace.WithSpanKind(trace.SpanKindClient))
-	defer span.End()
+	sender, ok := d.senders[target.Scheme]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unsupported scheme %q for destination %s", target.Scheme, target)
+	}
 
-	req, err := d.sender.NewCloudEventRequestWithTarget(ctx, url.String())
-	if err != nil {
-		return nil, nil, err
+	retryMax := 0
+	if retryConfig != nil {
+		retryMax = retryConfig.RetryMax
 	}
 
-	err = kncloudevents.WriteHttpRequestWithAdditionalHeaders(ctx, message, req, additionalHeaders)
-	if err != nil {
-		return nil, nil, err
+	var lastErr error
+	var lastInfo *DispatchExecutionInfo
+	for attempt := 0; attempt <= retryMax; attempt++ {
+		attemptCtx, span := trace.StartSpan(ctx, fmt.Sprintf("knative.dev/dispatch-attempt-%d", attempt), trace.WithSpanKind(trace.SpanKindClient))
+
+		var responseMessage binding.Message
+		var responseHeaders nethttp.Header
+		var err error
+		if tlsAware, ok := sender.(caCertsSender); ok {
+			responseMessage, responseHeaders, err = tlsAware.SendWithCACerts(attemptCtx, message, target, additionalHeaders, caCerts)
+		} else {
+			responseMessage, responseHeaders, err = sender.Send(attemptCtx, message, target, additionalHeaders)
+		}
+		span.End()
+
+		if err == nil {
+			if responseMessage == nil || responseMessage.ReadEncoding() == binding.EncodingUnknown {
+				return nil, nil, nil, nil
+			}
+			return responseMessage, responseHeaders, nil, nil
+		}
+
+		lastErr = err
+		var httpErr *httpResponseError
+		if errors.As(err, &httpErr) {
+			lastInfo = &DispatchExecutionInfo{ResponseCode: httpErr.StatusCode, ResponseBody: httpErr.Body}
+			if attempt == retryMax || !isRetryableCode(httpErr.StatusCode, retryConfig.RetryableCodes) {
+				break
+			}
+		} else if attempt == retryMax {
+			// Connection-level failure with no more attempts left.
+			break
+		}
+
+		d.logger.Debug("Retrying dispatch", zap.String("url", target.String()), zap.Int("attempt", attempt), zap.Error(err))
+		time.Sleep(retryConfig.backoff(attempt))
 	}
+	return nil, nil, lastInfo, lastErr
+}
 
-	response, err := d.sender.Send(req)
-	if err != nil {
-		return nil, nil, err
+// backoff returns the delay before the given zero-indexed retry attempt.
+func (c *RetryConfig) backoff(attempt int) time.Duration {
+	if c.BackoffPolicy == BackoffPolicyExponential {
+		return c.BackoffDelay * time.Duration(1<<uint(attempt))
+	}
+	return c.BackoffDelay * time.Duration(attempt+1)
+}
+
+var defaultRetryableCodes = []int{nethttp.StatusRequestTimeout, nethttp.StatusTooManyRequests}
+
+// isRetryableCode reports whether code should be retried: any 5xx, plus
+// whatever's in retryableCodes (defaultRetryableCodes if empty).
+func isRetryableCode(code int, retryableCodes []int) bool {
+	if code >= 500 {
+		return true
+	}
+	codes := retryableCodes
+	if len(codes) == 0 {
+		codes = defaultRetryableCodes
+	}
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
 	}
-	if isFailure(response.StatusCode) {
-		// Reject non-successful responses.
-		return nil, nil, fmt.Errorf("unexpected HTTP response, expected 2xx, got %d", response.StatusCode)
+	return false
+}
+
+// withDeadLetterExtensions returns message with the knativeerrordest,
+// knativeerrorcode and knativeerrordata CloudEvent extension attributes set
+// from destination and info, so the dead letter sink can diagnose why the
+// event landed there. info may be nil if the destination was never
+// reachable at all.
+func withDeadLetterExtensions(ctx context.Context, message binding.Message, destination *url.URL, info *DispatchExecutionInfo) (binding.Message, error) {
+	event, err := binding.ToEvent(ctx, message)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read event to annotate: %w", err)
 	}
-	responseMessage := http.NewMessageFromHttpResponse(response)
-	if responseMessage.ReadEncoding() == binding.EncodingUnknown {
-		d.logger.Debug("Response is a non event, discarding it", zap.Int("status_code", response.StatusCode))
-		return nil, nil, nil
+	event.SetExtension(deadLetterErrorDestinationExtension, destination.String())
+	if info != nil {
+		event.SetExtension(deadLetterErrorCodeExtension, info.ResponseCode)
+		event.SetExtension(deadLetterErrorDataExtension, string(info.ResponseBody))
 	}
-	return responseMessage, utils.PassThroughHeaders(response.Header), nil
+	annotated := binding.EventMessage(*event)
+	return &annotated, nil
 }
 
+// sanitizeURL returns URLs whose scheme has a registered Sender (including
+// https:// and non-HTTP protocol bindings) unchanged. Any other URL, whether
+// host-only or using an unregistered scheme, is rewritten to http://<host>/,
+// dropping its scheme and path.
 func (d *MessageDispatcherImpl) sanitizeURL(u *url.URL) *url.URL {
 	if u == nil {
 		return nil
 	}
-	if d.supportedSchemes.Has(u.Scheme) {
+	if _, ok := d.senders[u.Scheme]; ok {
 		// Already a URL with a known scheme.
 		return u
 	}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package channel
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"go.uber.org/zap"
+)
+
+// fakeRetrySender is a Sender test double that returns the status codes in
+// statuses in order, one per call to Send; a zero entry means success. It
+// records how many times Send was called.
+type fakeRetrySender struct {
+	statuses []int
+	calls    int
+}
+
+var _ Sender = (*fakeRetrySender)(nil)
+
+func (s *fakeRetrySender) Send(_ context.Context, _ binding.Message, _ *url.URL, _ nethttp.Header) (binding.Message, nethttp.Header, error) {
+	if s.calls >= len(s.statuses) {
+		panic("fakeRetrySender: more Send calls than statuses configured")
+	}
+	code := s.statuses[s.calls]
+	s.calls++
+	if code == 0 {
+		return nil, nil, nil
+	}
+	return nil, nil, &httpResponseError{StatusCode: code, Body: []byte("boom")}
+}
+
+func newTestDispatcher(sender Sender) *MessageDispatcherImpl {
+	return &MessageDispatcherImpl{
+		senders: map[string]Sender{"test": sender},
+		logger:  zap.NewNop(),
+	}
+}
+
+func TestExecuteRequestWithRetries(t *testing.T) {
+	target := &url.URL{Scheme: "test", Host: "example"}
+	message := binding.EventMessage(newTestEvent())
+
+	t.Run("exhausts RetryMax on persistent 5xx", func(t *testing.T) {
+		sender := &fakeRetrySender{statuses: []int{500, 500, 500}}
+		d := newTestDispatcher(sender)
+		retryConfig := &RetryConfig{RetryMax: 2, BackoffDelay: time.Microsecond}
+
+		_, _, info, err := d.executeRequestWithRetries(context.Background(), target, nil, message, nil, retryConfig)
+
+		if sender.calls != 3 {
+			t.Errorf("got %d Send calls, want 3 (1 initial + 2 retries)", sender.calls)
+		}
+		if err == nil {
+			t.Fatal("expected an error after exhausting retries, got nil")
+		}
+		if info == nil || info.ResponseCode != 500 {
+			t.Errorf("got DispatchExecutionInfo %+v, want ResponseCode 500", info)
+		}
+	})
+
+	t.Run("stops immediately on a non-retryable 4xx", func(t *testing.T) {
+		sender := &fakeRetrySender{statuses: []int{400}}
+		d := newTestDispatcher(sender)
+		retryConfig := &RetryConfig{RetryMax: 3, BackoffDelay: time.Microsecond}
+
+		_, _, info, err := d.executeRequestWithRetries(context.Background(), target, nil, message, nil, retryConfig)
+
+		if sender.calls != 1 {
+			t.Errorf("got %d Send calls, want 1 (no retries for a non-retryable code)", sender.calls)
+		}
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+		if info == nil || info.ResponseCode != 400 {
+			t.Errorf("got DispatchExecutionInfo %+v, want ResponseCode 400", info)
+		}
+	})
+
+	t.Run("honors RetryableCodes override", func(t *testing.T) {
+		sender := &fakeRetrySender{statuses: []int{400, 0}}
+		d := newTestDispatcher(sender)
+		retryConfig := &RetryConfig{RetryMax: 1, BackoffDelay: time.Microsecond, RetryableCodes: []int{400}}
+
+		_, _, info, err := d.executeRequestWithRetries(context.Background(), target, nil, message, nil, retryConfig)
+
+		if sender.calls != 2 {
+			t.Errorf("got %d Send calls, want 2 (400 retried because of the override, then succeeds)", sender.calls)
+		}
+		if err != nil {
+			t.Errorf("got error %v, want nil after the retried attempt succeeds", err)
+		}
+		if info != nil {
+			t.Errorf("got DispatchExecutionInfo %+v, want nil on eventual success", info)
+		}
+	})
+
+	t.Run("default retryable codes don't retry an unconfigured 4xx", func(t *testing.T) {
+		sender := &fakeRetrySender{statuses: []int{403}}
+		d := newTestDispatcher(sender)
+		retryConfig := &RetryConfig{RetryMax: 3, BackoffDelay: time.Microsecond}
+
+		_, _, _, err := d.executeRequestWithRetries(context.Background(), target, nil, message, nil, retryConfig)
+
+		if sender.calls != 1 {
+			t.Errorf("got %d Send calls, want 1", sender.calls)
+		}
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+	})
+}
+
+func TestIsRetryableCode(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           int
+		retryableCodes []int
+		want           bool
+	}{
+		{name: "5xx is always retryable", code: 503, want: true},
+		{name: "408 is retryable by default", code: nethttp.StatusRequestTimeout, want: true},
+		{name: "429 is retryable by default", code: nethttp.StatusTooManyRequests, want: true},
+		{name: "403 is not retryable by default", code: nethttp.StatusForbidden, want: false},
+		{name: "override replaces the default set", code: nethttp.StatusRequestTimeout, retryableCodes: []int{403}, want: false},
+		{name: "override adds a new retryable code", code: 403, retryableCodes: []int{403}, want: true},
+		{name: "5xx is retryable even with a narrower override", code: 503, retryableCodes: []int{403}, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isRetryableCode(test.code, test.retryableCodes); got != test.want {
+				t.Errorf("isRetryableCode(%d, %v) = %v, want %v", test.code, test.retryableCodes, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRetryConfigBackoff(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RetryConfig
+		attempt int
+		want   time.Duration
+	}{
+		{name: "linear first attempt", config: RetryConfig{BackoffPolicy: BackoffPolicyLinear, BackoffDelay: time.Second}, attempt: 0, want: time.Second},
+		{name: "linear third attempt", config: RetryConfig{BackoffPolicy: BackoffPolicyLinear, BackoffDelay: time.Second}, attempt: 2, want: 3 * time.Second},
+		{name: "exponential first attempt", config: RetryConfig{BackoffPolicy: BackoffPolicyExponential, BackoffDelay: time.Second}, attempt: 0, want: time.Second},
+		{name: "exponential third attempt", config: RetryConfig{BackoffPolicy: BackoffPolicyExponential, BackoffDelay: time.Second}, attempt: 2, want: 4 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.config.backoff(test.attempt); got != test.want {
+				t.Errorf("backoff(%d) = %v, want %v", test.attempt, got, test.want)
+			}
+		})
+	}
+}
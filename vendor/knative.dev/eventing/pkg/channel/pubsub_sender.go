@@ -0,0 +1,98 @@
+/*
+ * Copyright 2020 The Knative Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package channel
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+
+	cepubsub "github.com/cloudevents/sdk-go/protocol/pubsub/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"go.uber.org/zap"
+)
+
+// pubsubSender is the Sender registered for pubsub:// destinations, of the
+// form pubsub://project/topic. Like Kafka, Pub/Sub has no synchronous
+// response, so Send never returns a response message to forward to reply.
+type pubsubSender struct {
+	logger *zap.Logger
+
+	// clients caches the cloudevents.Client (and its underlying Pub/Sub
+	// client) for each target already dialed, keyed by target.String(), so
+	// Send doesn't pay a fresh client construction on every event.
+	clients clientCache
+}
+
+var _ Sender = (*pubsubSender)(nil)
+
+func newPubSubSender(logger *zap.Logger) *pubsubSender {
+	return &pubsubSender{logger: logger}
+}
+
+func (s *pubsubSender) Send(ctx context.Context, message binding.Message, target *url.URL, additionalHeaders nethttp.Header) (binding.Message, nethttp.Header, error) {
+	client, err := s.clientFor(ctx, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	event, err := binding.ToEvent(ctx, message)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read event to send to %s: %w", target, err)
+	}
+
+	if result := client.Send(ctx, *event); cloudevents.IsUndelivered(result) {
+		return nil, nil, fmt.Errorf("unable to send event to %s: %w", target, result)
+	}
+
+	s.logger.Debug("Dispatched event to pubsub", zap.String("url", target.String()))
+	return nil, nil, nil
+}
+
+// clientFor returns the cached cloudevents.Client for target, dialing and
+// caching one if this is the first Send to see it.
+func (s *pubsubSender) clientFor(ctx context.Context, target *url.URL) (cloudevents.Client, error) {
+	project, topic, err := parsePubSubTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.clients.loadOrDial(target.String(), func() (cloudevents.Client, protocolCloser, error) {
+		protocol, err := cepubsub.New(ctx, cepubsub.WithProjectID(project), cepubsub.WithTopicID(topic))
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to create pubsub protocol for %s: %w", target, err)
+		}
+		client, err := cloudevents.NewClient(protocol)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to create pubsub client for %s: %w", target, err)
+		}
+		return client, protocol, nil
+	})
+}
+
+// parsePubSubTarget splits a pubsub://project/topic destination into its
+// project and topic.
+func parsePubSubTarget(target *url.URL) (string, string, error) {
+	topic := strings.TrimPrefix(target.Path, "/")
+	if target.Host == "" || topic == "" || strings.Contains(topic, "/") {
+		return "", "", fmt.Errorf("pubsub destination %s must be of the form pubsub://project/topic", target)
+	}
+	return target.Host, topic, nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	brokerreconciler "knative.dev/eventing/pkg/client/injection/reconciler/eventing/v1alpha1/broker"
+	eventingv1alpha1listers "knative.dev/eventing/pkg/client/listers/eventing/v1alpha1"
+	"knative.dev/eventing/pkg/reconciler/broker/resources"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/network"
+	pkgreconciler "knative.dev/pkg/reconciler"
+	"knative.dev/pkg/resolver"
+)
+
+// ingressImage is the image used for a Broker's ingress Deployment. This
+// will move to a ConfigMap-driven knob once the broker image is cut, same as
+// every other component in this repo.
+const ingressImage = "gcr.io/knative-releases/knative.dev/eventing/cmd/broker/ingress"
+
+// Reconciler reconciles Brokers of this controller's class (see
+// NewController) into an ingress Deployment + Service backed by a ConfigMap
+// the ingress reads its configuration from, and surfaces the resulting
+// address on the Broker's status.
+type Reconciler struct {
+	deploymentLister appsv1listers.DeploymentLister
+	serviceLister    corev1listers.ServiceLister
+	configMapLister  corev1listers.ConfigMapLister
+	triggerLister    eventingv1alpha1listers.TriggerLister
+
+	// destinationResolver resolves spec.delivery.deadLetterSink into a URI
+	// for status.deadLetterSinkUri; see reconcileDeadLetterSink.
+	destinationResolver *resolver.URIResolver
+}
+
+// Check that our Reconciler implements the generated Interface.
+var _ brokerreconciler.Interface = (*Reconciler)(nil)
+var _ brokerreconciler.Finalizer = (*Reconciler)(nil)
+
+func (r *Reconciler) ReconcileKind(ctx context.Context, b *v1alpha1.Broker) pkgreconciler.Event {
+	if _, err := r.reconcileConfigMap(ctx, b); err != nil {
+		return fmt.Errorf("failed to reconcile broker config map: %w", err)
+	}
+
+	d, err := r.reconcileIngressDeployment(ctx, b)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ingress deployment: %w", err)
+	}
+	b.Status.PropagateIngressDeploymentAvailability(d)
+
+	svc, err := r.reconcileIngressService(ctx, b)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ingress service: %w", err)
+	}
+	b.Status.SetAddress(&apis.URL{
+		Scheme: "http",
+		Host:   network.GetServiceHostname(svc.Name, svc.Namespace),
+	})
+
+	if err := r.reconcileDeadLetterSink(ctx, b); err != nil {
+		return fmt.Errorf("failed to resolve spec.delivery.deadLetterSink: %w", err)
+	}
+
+	return pkgreconciler.NewEvent(corev1.EventTypeNormal, "BrokerReconciled", "Broker reconciled: \"%s/%s\"", b.Namespace, b.Name)
+}
+
+// reconcileDeadLetterSink resolves b.Spec.Delivery.DeadLetterSink (if set)
+// into a URI and surfaces it as b.Status.DeadLetterSinkURI, clearing it when
+// no dead letter sink is configured.
+func (r *Reconciler) reconcileDeadLetterSink(ctx context.Context, b *v1alpha1.Broker) error {
+	if b.Spec.Delivery == nil || b.Spec.Delivery.DeadLetterSink == nil {
+		b.Status.DeadLetterSinkURI = nil
+		return nil
+	}
+
+	uri, err := r.destinationResolver.URIFromDestination(ctx, *b.Spec.Delivery.DeadLetterSink, b)
+	if err != nil {
+		b.Status.DeadLetterSinkURI = nil
+		return err
+	}
+	b.Status.DeadLetterSinkURI = uri
+	return nil
+}
+
+func (r *Reconciler) FinalizeKind(ctx context.Context, b *v1alpha1.Broker) pkgreconciler.Event {
+	// Deployment, Service and ConfigMap all carry an owner reference to b,
+	// so Kubernetes garbage collection tears them down; nothing else to do.
+	return pkgreconciler.NewEvent(corev1.EventTypeNormal, "BrokerFinalized", "Broker finalized: \"%s/%s\"", b.Namespace, b.Name)
+}
+
+func (r *Reconciler) reconcileIngressDeployment(ctx context.Context, b *v1alpha1.Broker) (*appsv1.Deployment, error) {
+	want := resources.MakeIngressDeployment(b, ingressImage)
+
+	got, err := r.deploymentLister.Deployments(b.Namespace).Get(want.Name)
+	if apierrs.IsNotFound(err) {
+		return kubeclient.Get(ctx).AppsV1().Deployments(b.Namespace).Create(ctx, want, metav1.CreateOptions{})
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !equality.Semantic.DeepEqual(got.Spec, want.Spec) {
+		update := got.DeepCopy()
+		update.Spec = want.Spec
+		return kubeclient.Get(ctx).AppsV1().Deployments(b.Namespace).Update(ctx, update, metav1.UpdateOptions{})
+	}
+	return got, nil
+}
+
+func (r *Reconciler) reconcileIngressService(ctx context.Context, b *v1alpha1.Broker) (*corev1.Service, error) {
+	want := resources.MakeIngressService(b)
+
+	got, err := r.serviceLister.Services(b.Namespace).Get(want.Name)
+	if apierrs.IsNotFound(err) {
+		return kubeclient.Get(ctx).CoreV1().Services(b.Namespace).Create(ctx, want, metav1.CreateOptions{})
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !equality.Semantic.DeepEqual(got.Spec.Selector, want.Spec.Selector) || !equality.Semantic.DeepEqual(got.Spec.Ports, want.Spec.Ports) {
+		update := got.DeepCopy()
+		update.Spec.Selector = want.Spec.Selector
+		update.Spec.Ports = want.Spec.Ports
+		return kubeclient.Get(ctx).CoreV1().Services(b.Namespace).Update(ctx, update, metav1.UpdateOptions{})
+	}
+	return got, nil
+}
+
+func (r *Reconciler) reconcileConfigMap(ctx context.Context, b *v1alpha1.Broker) (*corev1.ConfigMap, error) {
+	want := resources.MakeConfigMap(b)
+
+	got, err := r.configMapLister.ConfigMaps(b.Namespace).Get(want.Name)
+	if apierrs.IsNotFound(err) {
+		return kubeclient.Get(ctx).CoreV1().ConfigMaps(b.Namespace).Create(ctx, want, metav1.CreateOptions{})
+	} else if err != nil {
+		return nil, err
+	}
+	// Trigger reconciliation (not added here) owns the ConfigMap's Data, so
+	// there's nothing further to converge once it exists.
+	return got, nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	"knative.dev/eventing/pkg/reconciler/broker/resources"
+	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
+)
+
+func newTestBroker() *v1alpha1.Broker {
+	return &v1alpha1.Broker{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-ns", Name: "test-broker"},
+	}
+}
+
+func newIndexer(objs ...interface{}) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objs {
+		if err := indexer.Add(obj); err != nil {
+			panic(err)
+		}
+	}
+	return indexer
+}
+
+func TestReconcileIngressDeployment(t *testing.T) {
+	b := newTestBroker()
+	want := resources.MakeIngressDeployment(b, ingressImage)
+
+	t.Run("create from scratch", func(t *testing.T) {
+		ctx, kubeClient := fakekubeclient.With(context.Background())
+		r := &Reconciler{deploymentLister: appsv1listers.NewDeploymentLister(newIndexer())}
+
+		if _, err := r.reconcileIngressDeployment(ctx, b); err != nil {
+			t.Fatalf("reconcileIngressDeployment() = %v", err)
+		}
+
+		created, err := kubeClient.AppsV1().Deployments(b.Namespace).Get(ctx, want.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected the Deployment to have been created: %v", err)
+		}
+		if !equality.Semantic.DeepEqual(created.Spec, want.Spec) {
+			t.Errorf("created Deployment spec = %+v, want %+v", created.Spec, want.Spec)
+		}
+	})
+
+	t.Run("no-op when nothing drifted", func(t *testing.T) {
+		existing := want.DeepCopy()
+		ctx, kubeClient := fakekubeclient.With(context.Background(), existing)
+		r := &Reconciler{deploymentLister: appsv1listers.NewDeploymentLister(newIndexer(existing))}
+
+		got, err := r.reconcileIngressDeployment(ctx, b)
+		if err != nil {
+			t.Fatalf("reconcileIngressDeployment() = %v", err)
+		}
+		if !equality.Semantic.DeepEqual(got.Spec, existing.Spec) {
+			t.Errorf("got Deployment spec = %+v, want unchanged %+v", got.Spec, existing.Spec)
+		}
+
+		actions := kubeClient.Actions()
+		for _, action := range actions {
+			if action.Matches("update", "deployments") {
+				t.Errorf("expected no update action when nothing drifted, got %+v", action)
+			}
+		}
+	})
+
+	t.Run("update on spec drift", func(t *testing.T) {
+		existing := want.DeepCopy()
+		existing.Spec.Template.Spec.Containers[0].Image = "stale-image"
+		ctx, kubeClient := fakekubeclient.With(context.Background(), existing)
+		r := &Reconciler{deploymentLister: appsv1listers.NewDeploymentLister(newIndexer(existing))}
+
+		got, err := r.reconcileIngressDeployment(ctx, b)
+		if err != nil {
+			t.Fatalf("reconcileIngressDeployment() = %v", err)
+		}
+		if !equality.Semantic.DeepEqual(got.Spec, want.Spec) {
+			t.Errorf("got Deployment spec = %+v, want converged to %+v", got.Spec, want.Spec)
+		}
+
+		updated, err := kubeClient.AppsV1().Deployments(b.Namespace).Get(ctx, want.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected the Deployment to still exist: %v", err)
+		}
+		if updated.Spec.Template.Spec.Containers[0].Image != want.Spec.Template.Spec.Containers[0].Image {
+			t.Errorf("got image %q, want %q", updated.Spec.Template.Spec.Containers[0].Image, want.Spec.Template.Spec.Containers[0].Image)
+		}
+	})
+}
+
+func TestReconcileIngressService(t *testing.T) {
+	b := newTestBroker()
+	want := resources.MakeIngressService(b)
+
+	t.Run("create from scratch", func(t *testing.T) {
+		ctx, kubeClient := fakekubeclient.With(context.Background())
+		r := &Reconciler{serviceLister: corev1listers.NewServiceLister(newIndexer())}
+
+		if _, err := r.reconcileIngressService(ctx, b); err != nil {
+			t.Fatalf("reconcileIngressService() = %v", err)
+		}
+
+		created, err := kubeClient.CoreV1().Services(b.Namespace).Get(ctx, want.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected the Service to have been created: %v", err)
+		}
+		if !equality.Semantic.DeepEqual(created.Spec.Selector, want.Spec.Selector) || !equality.Semantic.DeepEqual(created.Spec.Ports, want.Spec.Ports) {
+			t.Errorf("created Service spec = %+v, want %+v", created.Spec, want.Spec)
+		}
+	})
+
+	t.Run("no-op when nothing drifted", func(t *testing.T) {
+		existing := want.DeepCopy()
+		ctx, kubeClient := fakekubeclient.With(context.Background(), existing)
+		r := &Reconciler{serviceLister: corev1listers.NewServiceLister(newIndexer(existing))}
+
+		if _, err := r.reconcileIngressService(ctx, b); err != nil {
+			t.Fatalf("reconcileIngressService() = %v", err)
+		}
+
+		for _, action := range kubeClient.Actions() {
+			if action.Matches("update", "services") {
+				t.Errorf("expected no update action when nothing drifted, got %+v", action)
+			}
+		}
+	})
+
+	t.Run("update on spec drift", func(t *testing.T) {
+		existing := want.DeepCopy()
+		existing.Spec.Selector = map[string]string{"stale": "selector"}
+		ctx, kubeClient := fakekubeclient.With(context.Background(), existing)
+		r := &Reconciler{serviceLister: corev1listers.NewServiceLister(newIndexer(existing))}
+
+		got, err := r.reconcileIngressService(ctx, b)
+		if err != nil {
+			t.Fatalf("reconcileIngressService() = %v", err)
+		}
+		if !equality.Semantic.DeepEqual(got.Spec.Selector, want.Spec.Selector) {
+			t.Errorf("got Service selector = %+v, want converged to %+v", got.Spec.Selector, want.Spec.Selector)
+		}
+
+		updated, err := kubeClient.CoreV1().Services(b.Namespace).Get(ctx, want.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected the Service to still exist: %v", err)
+		}
+		if !equality.Semantic.DeepEqual(updated.Spec.Selector, want.Spec.Selector) {
+			t.Errorf("got persisted selector %+v, want %+v", updated.Spec.Selector, want.Spec.Selector)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+// ConfigMapName is the name of the ConfigMap holding a Broker's ingress
+// configuration.
+func ConfigMapName(b *v1alpha1.Broker) string {
+	return kmeta.ChildName(b.Name, "-broker-config")
+}
+
+// MakeConfigMap returns the (empty, reconciler-owned) ConfigMap a Broker's
+// ingress reads its configuration from. It is created with no Data;
+// Reconciler.reconcileConfigMap only creates it when missing and does not
+// write Triggers into it.
+func MakeConfigMap(b *v1alpha1.Broker) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            ConfigMapName(b),
+			Namespace:       b.Namespace,
+			Labels:          IngressLabels(b),
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(b)},
+		},
+	}
+}
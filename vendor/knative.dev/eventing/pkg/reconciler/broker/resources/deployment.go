@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"knative.dev/eventing/pkg/apis/eventing/v1alpha1"
+	"knative.dev/pkg/kmeta"
+)
+
+// IngressName is the name of the Deployment and Service fronting a Broker's
+// ingress.
+func IngressName(b *v1alpha1.Broker) string {
+	return kmeta.ChildName(b.Name, "-ingress")
+}
+
+// MakeIngressDeployment returns the Deployment running the given Broker's
+// ingress, reading its configuration from the ConfigMap named by
+// ConfigMapName.
+func MakeIngressDeployment(b *v1alpha1.Broker, image string) *appsv1.Deployment {
+	labels := IngressLabels(b)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            IngressName(b),
+			Namespace:       b.Namespace,
+			Labels:          labels,
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(b)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: "broker-ingress",
+					Containers: []corev1.Container{{
+						Name:  "ingress",
+						Image: image,
+						Env: []corev1.EnvVar{{
+							Name:  "BROKER_CONFIG_MAP",
+							Value: ConfigMapName(b),
+						}},
+						Ports: []corev1.ContainerPort{{
+							Name:          "http",
+							ContainerPort: 8080,
+						}},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// IngressLabels returns the labels applied to a Broker's ingress Deployment,
+// Service and Pods, and used to select between them.
+func IngressLabels(b *v1alpha1.Broker) map[string]string {
+	return map[string]string{
+		"eventing.knative.dev/broker":     b.Name,
+		"eventing.knative.dev/brokerRole": "ingress",
+	}
+}
+
+// ingressPort is the port the ingress Service exposes and forwards to the
+// Deployment's http container port.
+var ingressPort = intstr.FromInt(8080)
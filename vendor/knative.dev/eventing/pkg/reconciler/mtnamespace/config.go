@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtnamespace
+
+import (
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// brokerClassConfigMapName is watched (see NewController) for the Broker
+// class to stamp onto Brokers this controller provisions.
+const brokerClassConfigMapName = "config-br-defaults"
+
+// brokerClassConfigMapKey is the key within brokerClassConfigMapName holding
+// the class name.
+const brokerClassConfigMapKey = "default-broker-class"
+
+// defaultBrokerClass is used until brokerClassConfigMapName is observed, and
+// whenever it's missing brokerClassConfigMapKey or has been deleted.
+const defaultBrokerClass = "MTChannelBasedBroker"
+
+// brokerClassStore holds the current Broker class, safe for concurrent
+// reads from ReconcileKind and writes from the ConfigMap watch.
+type brokerClassStore struct {
+	value atomic.Value // string
+}
+
+func newBrokerClassStore() *brokerClassStore {
+	s := &brokerClassStore{}
+	s.value.Store(defaultBrokerClass)
+	return s
+}
+
+func (s *brokerClassStore) get() string {
+	return s.value.Load().(string)
+}
+
+// onConfigChanged is registered with configmap.Watcher in NewController.
+func (s *brokerClassStore) onConfigChanged(cm *corev1.ConfigMap) {
+	class := cm.Data[brokerClassConfigMapKey]
+	if class == "" {
+		class = defaultBrokerClass
+	}
+	s.value.Store(class)
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 	eventingclient "knative.dev/eventing/pkg/client/injection/client"
 	namespacereconciler "knative.dev/pkg/client/injection/kube/reconciler/core/v1/namespace"
@@ -27,6 +29,7 @@ import (
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
 	"knative.dev/eventing/pkg/client/injection/informers/eventing/v1beta1/broker"
 	"knative.dev/pkg/client/injection/kube/informers/core/v1/namespace"
 )
@@ -38,6 +41,14 @@ const (
 	// controllerAgentName is the string used by this controller to identify
 	// itself when creating events.
 	controllerAgentName = "knative-eventing-namespace-controller"
+
+	// injectionLabelKey is the label that opts a Namespace into having a
+	// default Broker provisioned into it; see isInjectionEnabled.
+	injectionLabelKey = "eventing.knative.dev/injection"
+
+	// injectionEnabledLabelValue is the only value of injectionLabelKey that
+	// enables provisioning.
+	injectionEnabledLabelValue = "enabled"
 )
 
 // NewController initializes the controller and is called by the generated code
@@ -53,18 +64,53 @@ func NewController(
 	r := &Reconciler{
 		eventingClientSet: eventingclient.Get(ctx),
 		brokerLister:      brokerInformer.Lister(),
+		brokerClass:       newBrokerClassStore(),
 	}
 
 	impl := namespacereconciler.NewImpl(ctx, r)
-	// TODO: filter label selector: on InjectionEnabledLabels()
 
 	logging.FromContext(ctx).Info("Setting up event handlers")
-	namespaceInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
-	brokerInformer.Informer().AddEventHandler(
-		cache.FilteringResourceEventHandler{
-			FilterFunc: controller.FilterGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Namespace")),
-			Handler:    controller.HandleAll(impl.EnqueueControllerOf),
-		})
+
+	cmw.Watch(brokerClassConfigMapName, r.brokerClass.onConfigChanged)
+
+	// Only Namespaces labeled injectionLabelKey=injectionEnabledLabelValue
+	// are enqueued. A Namespace that just had the label removed still
+	// reaches ReconcileKind: on an update whose old object passes FilterFunc
+	// and whose new one doesn't, FilteringResourceEventHandler calls OnDelete,
+	// which enqueues it, and ReconcileKind then deletes its Broker.
+	namespaceInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			ns, ok := obj.(*corev1.Namespace)
+			return ok && isInjectionEnabled(ns)
+		},
+		Handler: controller.HandleAll(impl.Enqueue),
+	})
+
+	// The provisioned Broker is owned by the Namespace (see makeBroker), not
+	// by anything informer-filterable by GVK, so re-enqueue the owning
+	// Namespace directly off the owner reference rather than via
+	// impl.EnqueueControllerOf, which assumes a namespaced owner.
+	brokerInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { enqueueOwningNamespace(impl, obj) },
+		UpdateFunc: func(_, obj interface{}) { enqueueOwningNamespace(impl, obj) },
+		DeleteFunc: func(obj interface{}) { enqueueOwningNamespace(impl, obj) },
+	})
 
 	return impl
 }
+
+// enqueueOwningNamespace re-enqueues the Namespace that controls obj (a
+// Broker provisioned by this controller), so that edits or deletes of the
+// Broker are reconciled back to the Namespace that should own it.
+func enqueueOwningNamespace(impl *controller.Impl, obj interface{}) {
+	b, ok := obj.(*eventingv1beta1.Broker)
+	if !ok {
+		return
+	}
+	owner := metav1.GetControllerOf(b)
+	if owner == nil || owner.Kind != "Namespace" {
+		return
+	}
+	// Namespace is cluster-scoped, so its reconcile key carries no namespace.
+	impl.EnqueueKey(types.NamespacedName{Name: owner.Name})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtnamespace
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	eventingclientset "knative.dev/eventing/pkg/client/clientset/versioned"
+	eventingv1beta1listers "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
+	namespacereconciler "knative.dev/pkg/client/injection/kube/reconciler/core/v1/namespace"
+	"knative.dev/pkg/ptr"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// brokerName is the name given to the Broker this controller provisions into
+// every labeled Namespace.
+const brokerName = "default"
+
+// brokerClassAnnotationKey is stamped onto every Broker this controller
+// creates so the class-based broker controller (see
+// knative.dev/eventing/pkg/reconciler/broker) picks it up.
+const brokerClassAnnotationKey = "eventing.knative.dev/broker.class"
+
+// Reconciler provisions a default Broker, of a configurable class, into
+// every Namespace labeled injectionLabelKey=injectionEnabledLabelValue, and
+// removes it again once that label is removed or the Namespace itself is
+// deleted.
+type Reconciler struct {
+	eventingClientSet eventingclientset.Interface
+	brokerLister      eventingv1beta1listers.BrokerLister
+
+	// brokerClass supplies the Broker class to stamp onto newly created
+	// Brokers, kept current by a ConfigMap watch set up in NewController.
+	brokerClass *brokerClassStore
+}
+
+// Check that our Reconciler implements the generated interfaces.
+var _ namespacereconciler.Interface = (*Reconciler)(nil)
+var _ namespacereconciler.Finalizer = (*Reconciler)(nil)
+
+func (r *Reconciler) ReconcileKind(ctx context.Context, ns *corev1.Namespace) pkgreconciler.Event {
+	if !isInjectionEnabled(ns) {
+		// The label was removed (or never set, though the informer's
+		// FilterFunc should keep that case from reaching us): make sure any
+		// Broker we previously provisioned is gone.
+		return r.deleteBroker(ctx, ns.Name)
+	}
+
+	_, err := r.brokerLister.Brokers(ns.Name).Get(brokerName)
+	if apierrs.IsNotFound(err) {
+		want := makeBroker(ns, r.brokerClass.get())
+		if _, err := r.eventingClientSet.EventingV1beta1().Brokers(ns.Name).Create(ctx, want, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create Broker %q in namespace %q: %w", brokerName, ns.Name, err)
+		}
+		return pkgreconciler.NewEvent(corev1.EventTypeNormal, "BrokerCreated", "Created Broker %q in namespace %q", brokerName, ns.Name)
+	} else if err != nil {
+		return fmt.Errorf("failed to get Broker %q in namespace %q: %w", brokerName, ns.Name, err)
+	}
+
+	return pkgreconciler.NewEvent(corev1.EventTypeNormal, "NamespaceReconciled", "Namespace reconciled: %q", ns.Name)
+}
+
+func (r *Reconciler) FinalizeKind(ctx context.Context, ns *corev1.Namespace) pkgreconciler.Event {
+	if err := r.deleteBroker(ctx, ns.Name); err != nil {
+		return err
+	}
+	return pkgreconciler.NewEvent(corev1.EventTypeNormal, "BrokerFinalized", "Deleted Broker %q in namespace %q", brokerName, ns.Name)
+}
+
+// deleteBroker deletes the Broker this controller provisions into namespace,
+// tolerating it already being gone.
+func (r *Reconciler) deleteBroker(ctx context.Context, namespace string) error {
+	err := r.eventingClientSet.EventingV1beta1().Brokers(namespace).Delete(ctx, brokerName, metav1.DeleteOptions{})
+	if err != nil && !apierrs.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Broker %q in namespace %q: %w", brokerName, namespace, err)
+	}
+	return nil
+}
+
+// isInjectionEnabled reports whether ns opts into Broker provisioning.
+func isInjectionEnabled(ns *corev1.Namespace) bool {
+	return ns.Labels[injectionLabelKey] == injectionEnabledLabelValue
+}
+
+// makeBroker returns the Broker this controller provisions into ns, owned by
+// ns so that deleting the Namespace directly (bypassing FinalizeKind, e.g.
+// via force-delete) still garbage collects it.
+func makeBroker(ns *corev1.Namespace, class string) *eventingv1beta1.Broker {
+	return &eventingv1beta1.Broker{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      brokerName,
+			Namespace: ns.Name,
+			Annotations: map[string]string{
+				brokerClassAnnotationKey: class,
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         "v1",
+				Kind:               "Namespace",
+				Name:               ns.Name,
+				UID:                ns.UID,
+				Controller:         ptr.Bool(true),
+				BlockOwnerDeletion: ptr.Bool(true),
+			}},
+		},
+	}
+}
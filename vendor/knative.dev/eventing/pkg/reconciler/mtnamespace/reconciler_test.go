@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mtnamespace
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	eventingv1beta1 "knative.dev/eventing/pkg/apis/eventing/v1beta1"
+	fakeeventingclient "knative.dev/eventing/pkg/client/injection/client/fake"
+	eventingv1beta1listers "knative.dev/eventing/pkg/client/listers/eventing/v1beta1"
+	pkgreconciler "knative.dev/pkg/reconciler"
+)
+
+// isSuccess reports whether event, as returned by ReconcileKind or
+// FinalizeKind, signals success: nil, or a Normal-type reconciler event.
+func isSuccess(event pkgreconciler.Event) bool {
+	if event == nil {
+		return true
+	}
+	var re *pkgreconciler.ReconcilerEvent
+	return pkgreconciler.EventAs(event, &re) && re.EventType == corev1.EventTypeNormal
+}
+
+func newTestNamespace(injectionEnabled bool) *corev1.Namespace {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", UID: "test-ns-uid"},
+	}
+	if injectionEnabled {
+		ns.Labels = map[string]string{injectionLabelKey: injectionEnabledLabelValue}
+	}
+	return ns
+}
+
+func newBrokerLister(brokers ...*eventingv1beta1.Broker) eventingv1beta1listers.BrokerLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, b := range brokers {
+		if err := indexer.Add(b); err != nil {
+			panic(err)
+		}
+	}
+	return eventingv1beta1listers.NewBrokerLister(indexer)
+}
+
+func TestReconcileKind(t *testing.T) {
+	t.Run("injection label present creates the default Broker", func(t *testing.T) {
+		ns := newTestNamespace(true)
+		ctx, eventingClient := fakeeventingclient.With(context.Background())
+		r := &Reconciler{
+			eventingClientSet: eventingClient,
+			brokerLister:      newBrokerLister(),
+			brokerClass:       newBrokerClassStore(),
+		}
+
+		if event := r.ReconcileKind(ctx, ns); !isSuccess(event) {
+			t.Fatalf("ReconcileKind() = %v", event)
+		}
+
+		got, err := eventingClient.EventingV1beta1().Brokers(ns.Name).Get(ctx, brokerName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected the default Broker to have been created: %v", err)
+		}
+		if got.Annotations[brokerClassAnnotationKey] == "" {
+			t.Errorf("expected the created Broker to carry %s", brokerClassAnnotationKey)
+		}
+	})
+
+	t.Run("injection label removed deletes the default Broker", func(t *testing.T) {
+		ns := newTestNamespace(false)
+		existing := makeBroker(newTestNamespace(true), "MTChannelBasedBroker")
+		ctx, eventingClient := fakeeventingclient.With(context.Background(), existing)
+		r := &Reconciler{
+			eventingClientSet: eventingClient,
+			brokerLister:      newBrokerLister(existing),
+			brokerClass:       newBrokerClassStore(),
+		}
+
+		if err := r.ReconcileKind(ctx, ns); err != nil {
+			t.Fatalf("ReconcileKind() = %v", err)
+		}
+
+		if _, err := eventingClient.EventingV1beta1().Brokers(ns.Name).Get(ctx, brokerName, metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+			t.Errorf("expected the Broker to have been deleted, got err = %v", err)
+		}
+	})
+}
+
+func TestFinalizeKind(t *testing.T) {
+	ns := newTestNamespace(true)
+	existing := makeBroker(ns, "MTChannelBasedBroker")
+	ctx, eventingClient := fakeeventingclient.With(context.Background(), existing)
+	r := &Reconciler{
+		eventingClientSet: eventingClient,
+		brokerLister:      newBrokerLister(existing),
+		brokerClass:       newBrokerClassStore(),
+	}
+
+	if event := r.FinalizeKind(ctx, ns); !isSuccess(event) {
+		t.Fatalf("FinalizeKind() = %v", event)
+	}
+
+	if _, err := eventingClient.EventingV1beta1().Brokers(ns.Name).Get(ctx, brokerName, metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Errorf("expected the Broker to have been deleted on finalize, got err = %v", err)
+	}
+}